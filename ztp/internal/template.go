@@ -15,9 +15,6 @@ License.
 package internal
 
 import (
-	"bytes"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -28,20 +25,34 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// Template is the interface implemented by the supported template engines. Don't create objects
+// that implement this interface directly, use the NewTemplate builder instead.
+type Template interface {
+	// Execute executes the template with the given name and passing the given input data. It
+	// writes the result to the given writer.
+	Execute(writer io.Writer, name string, data any) error
+
+	// Names returns the names of the templates.
+	Names() []string
+
+	// Render executes every template and writes the result to outFS. For each template name the
+	// mapping function decides the output path, the input data, the file mode and whether that
+	// template should be skipped. A file is only written when its content differs from what is
+	// already present in outFS, which makes repeated runs idempotent and suitable for GitOps flows.
+	Render(outFS WritableFS,
+		mapping func(name string) (outPath string, data any, mode fs.FileMode, skip bool)) (RenderReport, error)
+}
+
 // TemplateBuilder contains the data and logic needed to create templates. Don't create objects of
 // this type directly, use the NewTemplate function instead.
 type TemplateBuilder struct {
 	logger logr.Logger
 	fsys   fs.FS
 	dir    string
-}
-
-// Template is a template based on template.Template with some additional functions. Don't create
-// objects of this type directly, use the NewTemplate function instead.
-type Template struct {
-	logger   logr.Logger
-	names    []string
-	template *tmpl.Template
+	funcs  tmpl.FuncMap
+	secret SecretResolver
+	engine Engine
+	strict bool
 }
 
 // NewTemplate creates a builder that can the be used to create a template.
@@ -68,8 +79,63 @@ func (b *TemplateBuilder) SetDir(value string) *TemplateBuilder {
 	return b
 }
 
+// AddFunc registers a custom function that will be available to the templates in addition to the
+// built-in functions. This is optional, and can be called multiple times. If a function with the
+// same name already exists, either built-in or previously added, it will be replaced. Custom
+// functions are only available to the EngineGoText and EngineGoHTML engines.
+func (b *TemplateBuilder) AddFunc(name string, fn any) *TemplateBuilder {
+	if b.funcs == nil {
+		b.funcs = tmpl.FuncMap{}
+	}
+	b.funcs[name] = fn
+	return b
+}
+
+// AddFuncs registers a set of custom functions that will be available to the templates in addition
+// to the built-in functions. This is optional, and can be called multiple times. It behaves like
+// calling AddFunc once per entry of the given map.
+func (b *TemplateBuilder) AddFuncs(fns tmpl.FuncMap) *TemplateBuilder {
+	if b.funcs == nil {
+		b.funcs = tmpl.FuncMap{}
+	}
+	for name, fn := range fns {
+		b.funcs[name] = fn
+	}
+	return b
+}
+
+// SetSecretResolver sets the resolver that will be used by the `secret` template function to fetch
+// sensitive values, such as pull secrets, BMC credentials or TLS keys, so that they don't need to be
+// embedded in the input data structure. This is optional; if it isn't set then the `secret` function
+// will fail if used.
+func (b *TemplateBuilder) SetSecretResolver(value SecretResolver) *TemplateBuilder {
+	b.secret = value
+	return b
+}
+
+// SetEngine selects the template engine used to parse and execute the templates. This is optional;
+// if it isn't set then the engine is detected automatically for each file from its extension, and
+// EngineGoText is used for files whose extension doesn't match any other engine. Setting this
+// explicitly forces all the files to be parsed with the given engine, regardless of their
+// extension.
+func (b *TemplateBuilder) SetEngine(value Engine) *TemplateBuilder {
+	b.engine = value
+	return b
+}
+
+// SetStrict enables strict mode, which only applies to the EngineGoText and EngineGoHTML engines.
+// When enabled, a reference to a map key that doesn't exist makes execution of the template fail
+// instead of silently rendering `<no value>`, and the resulting error is an *ExecutionError that
+// contains the name of the template and, when it can be extracted from the underlying error, the
+// line and column where the problem was found and the action that triggered it. This is optional,
+// and disabled by default.
+func (b *TemplateBuilder) SetStrict(value bool) *TemplateBuilder {
+	b.strict = value
+	return b
+}
+
 // Build uses the configuration stored in the builder to create a new template.
-func (b *TemplateBuilder) Build() (result *Template, err error) {
+func (b *TemplateBuilder) Build() (result Template, err error) {
 	// Check parameters:
 	if b.logger.GetSink() == nil {
 		err = errors.New("logger is mandatory")
@@ -89,163 +155,101 @@ func (b *TemplateBuilder) Build() (result *Template, err error) {
 		}
 	}
 
-	// We need to create the object early because the some of the functions need the pointer:
-	t := &Template{
-		logger:   b.logger,
-		template: tmpl.New(""),
-	}
-
-	// Register the functions:
-	t.template.Funcs(tmpl.FuncMap{
-		"base64":  t.base64Func,
-		"execute": t.executeFunc,
-		"json":    t.jsonFunc,
-	})
-
-	// Find and parse the template files:
-	err = t.findFiles(fsys)
+	// Find the template files and group them by engine:
+	names, err := findTemplateFiles(fsys)
 	if err != nil {
 		return
 	}
-	err = t.parseFiles(fsys)
-	if err != nil {
-		return
+	groups := map[Engine][]string{}
+	for _, name := range names {
+		engine := b.engine
+		if engine == "" {
+			engine = detectEngine(name)
+		}
+		groups[engine] = append(groups[engine], name)
+	}
+
+	// Build one template object per engine, and combine them into a single one:
+	core := baseFuncs{
+		logger: b.logger,
+		secret: b.secret,
+		fsys:   fsys,
+	}
+	multi := &multiTemplate{
+		owners: map[string]Template{},
+	}
+	for engine, group := range groups {
+		var built Template
+		switch engine {
+		case EngineGoHTML:
+			built, err = buildHTMLTemplate(core, fsys, group, b.funcs, b.strict)
+		case EngineMustache:
+			built, err = buildMustacheTemplate(core, fsys, group)
+		case EngineJinja2:
+			built, err = buildJinja2Template(core, fsys, group)
+		default:
+			built, err = buildTextTemplate(core, fsys, group, b.funcs, b.strict)
+		}
+		if err != nil {
+			return
+		}
+		multi.templates = append(multi.templates, built)
+		for _, name := range built.Names() {
+			multi.names = append(multi.names, name)
+			multi.owners[name] = built
+		}
 	}
 
 	// Return the object:
-	result = t
+	result = multi
 	return
 }
 
-func (t *Template) findFiles(fsys fs.FS) error {
-	return fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+// errTemplateNotFound returns the error used when a template name doesn't exist in any of the
+// configured engines.
+func errTemplateNotFound(name string) error {
+	return fmt.Errorf("template '%s' doesn't exist", name)
+}
+
+// findTemplateFiles walks the given filesystem and returns the names of all the files it contains.
+func findTemplateFiles(fsys fs.FS) (names []string, err error) {
+	err = fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if entry.IsDir() {
 			return nil
 		}
-		t.names = append(t.names, name)
+		names = append(names, name)
 		return nil
 	})
+	return
 }
 
-func (t *Template) parseFiles(fsys fs.FS) error {
-	for _, name := range t.names {
-		err := t.parseFile(fsys, name)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+// multiTemplate is a Template that combines the templates produced by one or more engines into a
+// single one, dispatching Execute calls to the engine that owns each template name.
+type multiTemplate struct {
+	templates []Template
+	names     []string
+	owners    map[string]Template
 }
 
-func (t *Template) parseFile(fsys fs.FS, name string) error {
-	data, err := fs.ReadFile(fsys, name)
-	if err != nil {
-		return err
-	}
-	text := string(data)
-	_, err = t.template.New(name).Parse(text)
-	if err != nil {
-		return err
-	}
-	detail := t.logger.V(2)
-	if detail.Enabled() {
-		detail.Info(
-			"Parsed template",
-			"name", name,
-			"text", text,
-		)
-	}
-	return nil
-}
-
-// Execute executes the template with the given name and passing the given input data. It writes the
-// result to the given writer.
-func (t *Template) Execute(writer io.Writer, name string, data any) error {
-	buffer := &bytes.Buffer{}
-	err := t.template.ExecuteTemplate(buffer, name, data)
-	if err != nil {
-		return err
-	}
-	_, err = buffer.WriteTo(writer)
-	if err != nil {
-		return err
+// Execute is part of the Template interface.
+func (t *multiTemplate) Execute(writer io.Writer, name string, data any) error {
+	owner, ok := t.owners[name]
+	if !ok {
+		return errTemplateNotFound(name)
 	}
-	detail := t.logger.V(2)
-	if detail.Enabled() {
-		detail.Info(
-			"Executed template",
-			"name", name,
-			"data", data,
-			"text", buffer.String(),
-		)
-	}
-	return nil
+	return owner.Execute(writer, name, data)
 }
 
-// Names returns the names of the templates.
-func (t *Template) Names() []string {
+// Names is part of the Template interface.
+func (t *multiTemplate) Names() []string {
 	return slices.Clone(t.names)
 }
 
-// base64Func is a template function that encodes the given data using Base64 and returns the result
-// as a string. If the data is an array of bytes it will be encoded directly. If the data is a
-// string it will be converted to an array of bytes using the UTF-8 encoding. If the data implements
-// the fmt.Stringer interface it will be converted to a string using the String method, and then to
-// an array of bytes using the UTF-8 encoding. Any other kind of data will result in an error.
-func (t *Template) base64Func(value any) (result string, err error) {
-	var data []byte
-	switch typed := value.(type) {
-	case []byte:
-		data = typed
-	case string:
-		data = []byte(typed)
-	case fmt.Stringer:
-		data = []byte(typed.String())
-	default:
-		err = fmt.Errorf(
-			"don't know how to encode value of type %T",
-			value,
-		)
-		if err != nil {
-			return
-		}
-	}
-	result = base64.StdEncoding.EncodeToString(data)
-	return
+// Render is part of the Template interface.
+func (t *multiTemplate) Render(outFS WritableFS,
+	mapping func(name string) (outPath string, data any, mode fs.FileMode, skip bool)) (RenderReport, error) {
+	return renderTemplate(t, outFS, mapping)
 }
-
-// executeFunc is a template function similar to template.ExecuteTemplate but it returns the result
-// instead of writing it to the output. That is useful when some processing is needed after that,
-// for example, to encode the result using Base64:
-//
-//	{{ execute "my.tmpl" . | base64 }}
-func (t *Template) executeFunc(name string, data any) (result string, err error) {
-	buffer := &bytes.Buffer{}
-	executed := t.template.Lookup(name)
-	err = executed.Execute(buffer, data)
-	if err != nil {
-		return
-	}
-	result = buffer.String()
-	return
-}
-
-// jsonFunc is a template function that encodes the given data as JSON. This can be used, for
-// example, to encode as a JSON string the result of executing other function. For example, to
-// create a JSON document with a 'content' field that contains the text of the 'my.tmpl' template:
-//
-//	"content": {{ execute "my.tmpl" . | json }}
-//
-// Note how that the value of that 'content' field doesn't need to sorrounded by quotes, because the
-// 'json' function will generate a valid JSON string, including those quotes.
-func (t *Template) jsonFunc(data any) (result string, err error) {
-	text, err := json.Marshal(data)
-	if err != nil {
-		return
-	}
-	result = string(text)
-	return
-}
\ No newline at end of file
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+
+	"github.com/go-logr/logr"
+	tmpl "text/template"
+)
+
+// baseFuncs holds the state needed by the template functions that don't depend on a particular
+// template engine, such as `base64`, `json`, `secret`, `file` and `fileBase64`. It is embedded by
+// the concrete engine implementations so that they share a single implementation of these
+// functions.
+type baseFuncs struct {
+	logger logr.Logger
+	secret SecretResolver
+	fsys   fs.FS
+}
+
+// funcMap returns the subset of built-in functions that don't depend on a particular template
+// engine, combined with the expanded function library.
+func (f *baseFuncs) funcMap() tmpl.FuncMap {
+	result := tmpl.FuncMap{
+		"base64":     f.base64Func,
+		"json":       f.jsonFunc,
+		"secret":     f.secretFunc,
+		"file":       f.fileFunc,
+		"fileBase64": f.fileBase64Func,
+	}
+	for name, fn := range f.builtinFuncs() {
+		result[name] = fn
+	}
+	return result
+}
+
+// base64Func is a template function that encodes the given data using Base64 and returns the result
+// as a string. If the data is an array of bytes it will be encoded directly. If the data is a
+// string it will be converted to an array of bytes using the UTF-8 encoding. If the data implements
+// the fmt.Stringer interface it will be converted to a string using the String method, and then to
+// an array of bytes using the UTF-8 encoding. Any other kind of data will result in an error.
+func (f *baseFuncs) base64Func(value any) (result string, err error) {
+	var data []byte
+	switch typed := value.(type) {
+	case []byte:
+		data = typed
+	case string:
+		data = []byte(typed)
+	case fmt.Stringer:
+		data = []byte(typed.String())
+	default:
+		err = fmt.Errorf(
+			"don't know how to encode value of type %T",
+			value,
+		)
+		if err != nil {
+			return
+		}
+	}
+	result = base64.StdEncoding.EncodeToString(data)
+	return
+}
+
+// jsonFunc is a template function that encodes the given data as JSON. This can be used, for
+// example, to encode as a JSON string the result of executing other function. For example, to
+// create a JSON document with a 'content' field that contains the text of the 'my.tmpl' template:
+//
+//	"content": {{ execute "my.tmpl" . | json }}
+//
+// Note how that the value of that 'content' field doesn't need to sorrounded by quotes, because the
+// 'json' function will generate a valid JSON string, including those quotes.
+func (f *baseFuncs) jsonFunc(data any) (result string, err error) {
+	text, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	result = string(text)
+	return
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"path"
+	"strings"
+)
+
+// Engine identifies one of the template engines supported by TemplateBuilder.
+type Engine string
+
+const (
+	// EngineGoText uses the standard library `text/template` package. This is the default engine,
+	// and the one used for files whose extension doesn't match any other engine.
+	EngineGoText Engine = "go-text"
+
+	// EngineGoHTML uses the standard library `html/template` package, which automatically escapes
+	// values according to the HTML context where they are used. This is useful when the rendered
+	// output is going to be embedded inside an HTML document.
+	EngineGoHTML Engine = "go-html"
+
+	// EngineMustache uses the logic-less Mustache template language.
+	EngineMustache Engine = "mustache"
+
+	// EngineJinja2 uses the Jinja2 template language, familiar to operators who write Ansible
+	// playbooks.
+	EngineJinja2 Engine = "jinja2"
+)
+
+// engineExtensions maps the file extensions that are recognized automatically to the engine that
+// should be used to parse and execute them.
+var engineExtensions = map[string]Engine{
+	".tmpl":     EngineGoText,
+	".gotmpl":   EngineGoText,
+	".html":     EngineGoHTML,
+	".gohtml":   EngineGoHTML,
+	".mustache": EngineMustache,
+	".j2":       EngineJinja2,
+}
+
+// detectEngine returns the engine that should be used to parse and execute the file with the given
+// name, according to its extension. Files with an unrecognized extension use EngineGoText.
+func detectEngine(name string) Engine {
+	ext := strings.ToLower(path.Ext(name))
+	engine, ok := engineExtensions[ext]
+	if !ok {
+		return EngineGoText
+	}
+	return engine
+}
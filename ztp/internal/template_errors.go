@@ -0,0 +1,81 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// executionErrorPattern matches the messages produced by the text/template and html/template
+// packages when execution of a template fails, for example:
+//
+//	template: my.tmpl:12:12: executing "my.tmpl" at <.Foo>: map has no entry for key "Foo"
+//
+// The capture groups extract the template name, the line and column where the problem was found,
+// and the action that triggered it.
+var executionErrorPattern = regexp.MustCompile(
+	`^template: (.+):(\d+):(\d+): executing ".*" at (<.*>): (.*)$`,
+)
+
+// ExecutionError is returned when execution of a strict template fails. It wraps the underlying
+// error adding the name of the template, the line and column where the problem was found, and the
+// action that triggered it, when that information can be extracted from the underlying error.
+type ExecutionError struct {
+	Name   string
+	Line   int
+	Column int
+	Action string
+	Err    error
+}
+
+// Error is part of the error interface.
+func (e *ExecutionError) Error() string {
+	if e.Action == "" {
+		return fmt.Sprintf("failed to execute template '%s': %v", e.Name, e.Err)
+	}
+	return fmt.Sprintf(
+		"failed to execute template '%s' at line %d column %d, in action '%s': %v",
+		e.Name, e.Line, e.Column, e.Action, e.Err,
+	)
+}
+
+// Unwrap allows errors.Is and errors.As to reach the underlying error.
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// wrapExecutionError wraps the given execution error, extracting the line, column and action from
+// its message when possible. If the message doesn't have the expected format the error is wrapped
+// unchanged, with only the template name added.
+func wrapExecutionError(name string, err error) error {
+	matches := executionErrorPattern.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return &ExecutionError{
+			Name: name,
+			Err:  err,
+		}
+	}
+	line, _ := strconv.Atoi(matches[2])
+	column, _ := strconv.Atoi(matches[3])
+	return &ExecutionError{
+		Name:   matches[1],
+		Line:   line,
+		Column: column,
+		Action: matches[4],
+		Err:    err,
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-logr/logr"
+)
+
+func TestStrictModeWrapsMissingKeyAsExecutionError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cm.yaml": &fstest.MapFile{
+			Data: []byte("value: {{ .Missing }}\n"),
+		},
+	}
+	core := baseFuncs{
+		logger: logr.Discard(),
+		fsys:   fsys,
+	}
+	tmplObj, err := buildTextTemplate(core, fsys, []string{"cm.yaml"}, nil, true)
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "cm.yaml", map[string]any{})
+	if err == nil {
+		t.Fatal("expected an error executing a strict template with a missing key")
+	}
+
+	var execErr *ExecutionError
+	if !errors.As(err, &execErr) {
+		t.Fatalf("expected an *ExecutionError, got %T: %v", err, err)
+	}
+	if execErr.Name != "cm.yaml" {
+		t.Errorf("unexpected template name: got '%s', want 'cm.yaml'", execErr.Name)
+	}
+	if execErr.Line == 0 {
+		t.Errorf("expected a non-zero line number")
+	}
+}
+
+func TestNonStrictModeRendersMissingKeyAsNoValue(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cm.yaml": &fstest.MapFile{
+			Data: []byte("value: {{ .Missing }}\n"),
+		},
+	}
+	core := baseFuncs{
+		logger: logr.Discard(),
+		fsys:   fsys,
+	}
+	tmplObj, err := buildTextTemplate(core, fsys, []string{"cm.yaml"}, nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "cm.yaml", map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error executing a non-strict template: %v", err)
+	}
+	if buffer.String() != "value: <no value>\n" {
+		t.Fatalf("unexpected output: %q", buffer.String())
+	}
+}
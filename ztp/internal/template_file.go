@@ -0,0 +1,60 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+)
+
+// fileFunc is a template function that reads a file from the filesystem that the template was
+// configured with and returns its contents as a string. This is analogous to Terraform's `file`
+// function. The path is always resolved relative to the root of that filesystem, which acts as a
+// sandbox, so paths that try to escape it, for example by using `..`, are rejected:
+//
+//	{{ file "files/pull-secret.json" }}
+func (f *baseFuncs) fileFunc(path string) (result string, err error) {
+	data, err := f.readSandboxedFile(path)
+	if err != nil {
+		return
+	}
+	result = string(data)
+	return
+}
+
+// fileBase64Func is like fileFunc but it returns the contents of the file encoded using Base64. This
+// is analogous to Terraform's `filebase64` function, and is useful to embed binary files, such as
+// images or certificates, inside a manifest:
+//
+//	{{ fileBase64 "files/tls.crt" }}
+func (f *baseFuncs) fileBase64Func(path string) (result string, err error) {
+	data, err := f.readSandboxedFile(path)
+	if err != nil {
+		return
+	}
+	result = base64.StdEncoding.EncodeToString(data)
+	return
+}
+
+// readSandboxedFile reads the file at the given path from the template filesystem, rejecting paths
+// that aren't valid according to the rules of the io/fs package, so that it isn't possible to escape
+// the root of that filesystem, for example by using a path that contains `..`.
+func (f *baseFuncs) readSandboxedFile(path string) ([]byte, error) {
+	if !fs.ValidPath(path) {
+		return nil, fmt.Errorf("path '%s' isn't valid", path)
+	}
+	return fs.ReadFile(f.fsys, path)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadSandboxedFileReadsExistingFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"files/pull-secret.json": &fstest.MapFile{
+			Data: []byte(`{"auths":{}}`),
+		},
+	}
+	core := baseFuncs{fsys: fsys}
+
+	data, err := core.readSandboxedFile("files/pull-secret.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"auths":{}}` {
+		t.Fatalf("unexpected content: %s", data)
+	}
+}
+
+func TestReadSandboxedFileRejectsEscapingPaths(t *testing.T) {
+	fsys := fstest.MapFS{
+		"files/pull-secret.json": &fstest.MapFile{
+			Data: []byte(`{"auths":{}}`),
+		},
+	}
+	core := baseFuncs{fsys: fsys}
+
+	cases := []string{
+		"../escape.json",
+		"files/../../escape.json",
+		"/etc/passwd",
+	}
+	for _, path := range cases {
+		_, err := core.readSandboxedFile(path)
+		if err == nil {
+			t.Errorf("expected an error reading sandboxed path '%s', got none", path)
+		}
+	}
+}
+
+func TestReadSandboxedFileRejectsMissingFile(t *testing.T) {
+	fsys := fstest.MapFS{}
+	core := baseFuncs{fsys: fsys}
+
+	_, err := core.readSandboxedFile("files/missing.json")
+	if err == nil {
+		t.Fatal("expected an error reading a file that doesn't exist")
+	}
+}
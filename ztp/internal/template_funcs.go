@@ -0,0 +1,392 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tmpl "text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// builtinFuncs returns the expanded function library that is available to the templates of the
+// EngineGoText and EngineGoHTML engines; the EngineMustache and EngineJinja2 engines don't register
+// it. It is
+// inspired by the Sprig function library that is widely used by Helm and similar tools, and it adds
+// the kind of helpers that are commonly needed to generate YAML manifests: string manipulation,
+// defaults, dictionaries, lists, date formatting, YAML encoding and decoding, hashing and indentation.
+func (f *baseFuncs) builtinFuncs() tmpl.FuncMap {
+	return tmpl.FuncMap{
+		// String manipulation:
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      titleFunc,
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"trunc":      truncFunc,
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       joinFunc,
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"quote":      func(s string) string { return strconv.Quote(s) },
+		"squote":     func(s string) string { return "'" + strings.ReplaceAll(s, "'", `\'`) + "'" },
+		"indent":     indentFunc,
+		"nindent":    nindentFunc,
+
+		// Defaults:
+		"default": defaultFunc,
+		"empty":   emptyFunc,
+
+		// Dictionaries:
+		"dict":   dictFunc,
+		"get":    getFunc,
+		"hasKey": hasKeyFunc,
+		"keys":   keysFunc,
+
+		// Lists:
+		"list":   listFunc,
+		"first":  firstFunc,
+		"last":   lastFunc,
+		"append": appendFunc,
+		"uniq":   uniqFunc,
+
+		// Date formatting:
+		"now":        time.Now,
+		"dateFormat": dateFormatFunc,
+
+		// YAML encoding and decoding:
+		"toYaml":   toYamlFunc,
+		"fromYaml": fromYamlFunc,
+
+		// Hashing:
+		"sha256sum": sha256sumFunc,
+		"md5sum":    md5sumFunc,
+
+		// Environment lookup:
+		"env":       os.Getenv,
+		"expandEnv": os.ExpandEnv,
+
+		// UUID generation:
+		"uuid": uuidFunc,
+	}
+}
+
+// titleFunc returns a copy of the string with the first letter of each word converted to upper case.
+func titleFunc(s string) string {
+	runes := []rune(s)
+	start := true
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			start = true
+			continue
+		}
+		if start {
+			runes[i] = unicode.ToUpper(r)
+			start = false
+		}
+	}
+	return string(runes)
+}
+
+// truncFunc returns the first 'length' characters of the string, or the whole string if it is
+// shorter than that.
+func truncFunc(length int, s string) string {
+	runes := []rune(s)
+	if length < 0 || length >= len(runes) {
+		return s
+	}
+	return string(runes[:length])
+}
+
+// joinFunc joins the elements of a list of strings using the given separator.
+func joinFunc(sep string, list []string) string {
+	return strings.Join(list, sep)
+}
+
+// indentFunc adds the given number of spaces at the beginning of every line of the string.
+func indentFunc(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = pad + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// nindentFunc is like indentFunc but it also adds a new line at the beginning, which is convenient
+// when embedding a multi-line value inside a YAML document, for example:
+//
+//	data:
+//	  config.yaml: |
+//	{{- .Config | nindent 4 }}
+func nindentFunc(spaces int, s string) string {
+	return "\n" + indentFunc(spaces, s)
+}
+
+// defaultFunc returns the given value, unless it is the zero value for its type, in which case it
+// returns the default.
+func defaultFunc(def, given any) any {
+	if emptyFunc(given) {
+		return def
+	}
+	return given
+}
+
+// emptyFunc returns true if the given value is the zero value for its type, for example an empty
+// string, a zero number of any kind, a nil pointer or an empty slice or map. It uses reflection
+// instead of a type switch so that it also works for types that a type switch would miss, such as
+// the float64 that encoding/json and sigs.k8s.io/yaml use to decode numbers into an `any`.
+func emptyFunc(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// dictFunc creates a map from a list of alternating keys and values. The keys must be strings.
+func dictFunc(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires an even number of arguments, got %d", len(pairs))
+	}
+	result := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings, got %T", pairs[i])
+		}
+		result[key] = pairs[i+1]
+	}
+	return result, nil
+}
+
+// mapValueOf uses reflection to check that the given value is a map with string keys, and returns
+// its reflect.Value if so. It is used by the dictionary functions so that they work with any
+// concretely typed map, such as map[string]string, and not just map[string]any: templates are
+// commonly fed data that was decoded from JSON or YAML into a specific struct field, and Go doesn't
+// let a function parameter typed map[string]any accept a map[string]string, even though the
+// template language doesn't make that distinction.
+func mapValueOf(d any) (reflect.Value, error) {
+	v := reflect.ValueOf(d)
+	if v.Kind() != reflect.Map {
+		return reflect.Value{}, fmt.Errorf("expected a map, got %T", d)
+	}
+	if v.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}, fmt.Errorf("expected a map with string keys, got %T", d)
+	}
+	return v, nil
+}
+
+// sliceValueOf uses reflection to check that the given value is a slice or array, and returns its
+// reflect.Value if so. It is used by the list functions for the same reason mapValueOf is used by
+// the dictionary functions: to accept any concretely typed slice, such as []string, not just
+// []any.
+func sliceValueOf(list any) (reflect.Value, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return reflect.Value{}, fmt.Errorf("expected a list, got %T", list)
+	}
+	return v, nil
+}
+
+// toAnySlice converts the given slice or array, of any element type, into a []any containing the
+// same elements in the same order.
+func toAnySlice(list any) ([]any, error) {
+	v, err := sliceValueOf(list)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, v.Len())
+	for i := range result {
+		result[i] = v.Index(i).Interface()
+	}
+	return result, nil
+}
+
+// getFunc returns the value associated to the given key in the given dictionary, or nil if there is
+// no such key.
+func getFunc(d any, key string) (any, error) {
+	v, err := mapValueOf(d)
+	if err != nil {
+		return nil, err
+	}
+	value := v.MapIndex(reflect.ValueOf(key))
+	if !value.IsValid() {
+		return nil, nil
+	}
+	return value.Interface(), nil
+}
+
+// hasKeyFunc returns true if the given dictionary contains the given key.
+func hasKeyFunc(d any, key string) (bool, error) {
+	v, err := mapValueOf(d)
+	if err != nil {
+		return false, err
+	}
+	return v.MapIndex(reflect.ValueOf(key)).IsValid(), nil
+}
+
+// keysFunc returns the keys of the given dictionary, sorted alphabetically so that ranging over
+// them produces the same output on every run. This matters for templates executed through
+// Template.Render, whose change detection depends on rendering the same content for unchanged
+// input.
+func keysFunc(d any) ([]string, error) {
+	v, err := mapValueOf(d)
+	if err != nil {
+		return nil, err
+	}
+	keys := v.MapKeys()
+	result := make([]string, len(keys))
+	for i, key := range keys {
+		result[i] = key.String()
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// listFunc creates a list from the given items.
+func listFunc(items ...any) []any {
+	return items
+}
+
+// firstFunc returns the first item of the given list, or nil if the list is empty.
+func firstFunc(list any) (any, error) {
+	items, err := toAnySlice(list)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return items[0], nil
+}
+
+// lastFunc returns the last item of the given list, or nil if the list is empty.
+func lastFunc(list any) (any, error) {
+	items, err := toAnySlice(list)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return items[len(items)-1], nil
+}
+
+// appendFunc returns a new list with the given item added at the end.
+func appendFunc(list any, item any) ([]any, error) {
+	items, err := toAnySlice(list)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, len(items), len(items)+1)
+	copy(result, items)
+	return append(result, item), nil
+}
+
+// uniqFunc returns a new list containing only the first occurrence of each item. Items are compared
+// with reflect.DeepEqual instead of being used as map keys, so that unhashable items, such as maps
+// or slices decoded from YAML or JSON, don't make it panic.
+func uniqFunc(list any) ([]any, error) {
+	items, err := toAnySlice(list)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, 0, len(items))
+	for _, item := range items {
+		duplicate := false
+		for _, kept := range result {
+			if reflect.DeepEqual(kept, item) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// dateFormatFunc formats the given time using a Go reference layout, for example "2006-01-02".
+func dateFormatFunc(layout string, value time.Time) string {
+	return value.Format(layout)
+}
+
+// toYamlFunc encodes the given value as YAML. This is useful to embed a Go data structure, such as
+// the result of calling fromYaml, inside a YAML document.
+func toYamlFunc(value any) (string, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// fromYamlFunc decodes the given YAML text into a Go data structure.
+func fromYamlFunc(text string) (any, error) {
+	var result any
+	err := yaml.Unmarshal([]byte(text), &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sha256sumFunc returns the SHA256 hash of the given string, encoded as a hexadecimal string.
+func sha256sumFunc(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// md5sumFunc returns the MD5 hash of the given string, encoded as a hexadecimal string.
+func md5sumFunc(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// uuidFunc generates a random RFC 4122 version 4 UUID.
+func uuidFunc() (string, error) {
+	buffer := make([]byte, 16)
+	_, err := rand.Read(buffer)
+	if err != nil {
+		return "", err
+	}
+	buffer[6] = (buffer[6] & 0x0f) | 0x40
+	buffer[8] = (buffer[8] & 0x3f) | 0x80
+	return fmt.Sprintf(
+		"%x-%x-%x-%x-%x",
+		buffer[0:4], buffer[4:6], buffer[6:8], buffer[8:10], buffer[10:16],
+	), nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import "testing"
+
+func TestKeysFuncAcceptsConcretelyTypedMap(t *testing.T) {
+	result, err := keysFunc(map[string]string{"b": "2", "a": "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 || result[0] != "a" || result[1] != "b" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
+
+func TestGetAndHasKeyFuncAcceptConcretelyTypedMap(t *testing.T) {
+	d := map[string]string{"a": "1"}
+
+	value, err := getFunc(d, "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "1" {
+		t.Fatalf("unexpected value: %v", value)
+	}
+
+	has, err := hasKeyFunc(d, "b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if has {
+		t.Fatal("expected hasKey to return false for a missing key")
+	}
+}
+
+func TestGetFuncRejectsNonMap(t *testing.T) {
+	_, err := getFunc("not a map", "a")
+	if err == nil {
+		t.Fatal("expected an error getting a key from a non-map value")
+	}
+}
+
+func TestFirstAndLastFuncAcceptConcretelyTypedSlice(t *testing.T) {
+	list := []string{"a", "b", "c"}
+
+	first, err := firstFunc(list)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != "a" {
+		t.Fatalf("unexpected first item: %v", first)
+	}
+
+	last, err := lastFunc(list)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last != "c" {
+		t.Fatalf("unexpected last item: %v", last)
+	}
+}
+
+func TestFirstFuncReturnsNilForEmptyList(t *testing.T) {
+	first, err := firstFunc([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != nil {
+		t.Fatalf("expected nil, got %v", first)
+	}
+}
+
+func TestFirstFuncRejectsNonList(t *testing.T) {
+	_, err := firstFunc("not a list")
+	if err == nil {
+		t.Fatal("expected an error getting the first item of a non-list value")
+	}
+}
+
+func TestAppendFuncAcceptsConcretelyTypedSlice(t *testing.T) {
+	result, err := appendFunc([]string{"a", "b"}, "c")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 3 || result[2] != "c" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
+
+func TestUniqFuncAcceptsConcretelyTypedSlice(t *testing.T) {
+	result, err := uniqFunc([]string{"a", "b", "a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 || result[0] != "a" || result[1] != "b" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
+
+func TestUniqFuncHandlesUnhashableItems(t *testing.T) {
+	list := []map[string]any{
+		{"a": 1},
+		{"a": 1},
+		{"a": 2},
+	}
+	result, err := uniqFunc(list)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-logr/logr"
+)
+
+func TestHTMLTemplateEscapesValues(t *testing.T) {
+	fsys := fstest.MapFS{
+		"page.html": &fstest.MapFile{
+			Data: []byte("<p>{{ .Name }}</p>"),
+		},
+	}
+	core := baseFuncs{
+		logger: logr.Discard(),
+		fsys:   fsys,
+	}
+	tmplObj, err := buildHTMLTemplate(core, fsys, []string{"page.html"}, nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "page.html", map[string]any{"Name": "<script>"})
+	if err != nil {
+		t.Fatalf("unexpected error executing the template: %v", err)
+	}
+	if buffer.String() != "<p>&lt;script&gt;</p>" {
+		t.Fatalf("unexpected output: %q", buffer.String())
+	}
+
+	if len(tmplObj.Names()) != 1 || tmplObj.Names()[0] != "page.html" {
+		t.Fatalf("unexpected names: %v", tmplObj.Names())
+	}
+}
+
+func TestHTMLTemplateStrictModeWrapsMissingKey(t *testing.T) {
+	fsys := fstest.MapFS{
+		"page.html": &fstest.MapFile{
+			Data: []byte("<p>{{ .Missing }}</p>"),
+		},
+	}
+	core := baseFuncs{
+		logger: logr.Discard(),
+		fsys:   fsys,
+	}
+	tmplObj, err := buildHTMLTemplate(core, fsys, []string{"page.html"}, nil, true)
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "page.html", map[string]any{})
+	if err == nil {
+		t.Fatal("expected an error executing a strict template with a missing key")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/nikolalohinski/gonja/v2"
+	"github.com/nikolalohinski/gonja/v2/exec"
+	"golang.org/x/exp/slices"
+)
+
+// jinja2Template is the Template implementation for the EngineJinja2 engine, which uses the Jinja2
+// syntax familiar from Ansible playbooks. Don't create objects of this type directly, use the
+// NewTemplate builder instead.
+type jinja2Template struct {
+	baseFuncs
+	names    []string
+	compiled map[string]*exec.Template
+}
+
+// buildJinja2Template parses the given files as Jinja2 templates and returns a Template that
+// executes them.
+func buildJinja2Template(core baseFuncs, fsys fs.FS, names []string) (result *jinja2Template, err error) {
+	t := &jinja2Template{
+		baseFuncs: core,
+		compiled:  map[string]*exec.Template{},
+	}
+	for _, name := range names {
+		var data []byte
+		data, err = fs.ReadFile(fsys, name)
+		if err != nil {
+			return
+		}
+		var parsed *exec.Template
+		parsed, err = gonja.FromString(string(data))
+		if err != nil {
+			return
+		}
+		t.compiled[name] = parsed
+		t.names = append(t.names, name)
+	}
+	result = t
+	return
+}
+
+// Execute is part of the Template interface. Unlike the Go template engines, Jinja2 templates can
+// only reference top-level names of a mapping, so the input data is exposed at the top level the
+// same way a `map[string]any` would be: if it isn't already one, it is round-tripped through JSON
+// to turn its fields into top-level names. Data that doesn't encode to a JSON object, for example a
+// slice or a scalar, is rejected with an error instead of being silently unavailable to the
+// template.
+func (t *jinja2Template) Execute(writer io.Writer, name string, data any) error {
+	parsed, ok := t.compiled[name]
+	if !ok {
+		return errTemplateNotFound(name)
+	}
+	context, err := jinja2Context(data)
+	if err != nil {
+		return err
+	}
+	return parsed.Execute(writer, exec.NewContext(context))
+}
+
+// jinja2Context converts the given data into the map of top-level names that gonja needs. Maps are
+// used directly; any other non-nil value is round-tripped through JSON so that its fields become
+// top-level names, mirroring how the Go template engines expose struct fields.
+func jinja2Context(data any) (map[string]any, error) {
+	if data == nil {
+		return map[string]any{}, nil
+	}
+	if context, ok := data.(map[string]any); ok {
+		return context, nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode data of type %T for a jinja2 template: %w", data, err)
+	}
+	var context map[string]any
+	err = json.Unmarshal(encoded, &context)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"jinja2 templates require data that is a map or that encodes as a JSON object, got %T",
+			data,
+		)
+	}
+	return context, nil
+}
+
+// Names is part of the Template interface.
+func (t *jinja2Template) Names() []string {
+	return slices.Clone(t.names)
+}
+
+// Render is part of the Template interface.
+func (t *jinja2Template) Render(outFS WritableFS,
+	mapping func(name string) (outPath string, data any, mode fs.FileMode, skip bool)) (RenderReport, error) {
+	return renderTemplate(t, outFS, mapping)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+)
+
+type jinja2TestStruct struct {
+	Value string `json:"value"`
+}
+
+func TestJinja2TemplateExposesMapDataAtTopLevel(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cm.yaml": &fstest.MapFile{
+			Data: []byte("value: {{ value }}\n"),
+		},
+	}
+	core := baseFuncs{fsys: fsys}
+	tmplObj, err := buildJinja2Template(core, fsys, []string{"cm.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "cm.yaml", map[string]any{"value": "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error executing the template: %v", err)
+	}
+	if buffer.String() != "value: hello\n" {
+		t.Fatalf("unexpected output: %q", buffer.String())
+	}
+}
+
+func TestJinja2TemplateExposesStructFieldsAtTopLevel(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cm.yaml": &fstest.MapFile{
+			Data: []byte("value: {{ value }}\n"),
+		},
+	}
+	core := baseFuncs{fsys: fsys}
+	tmplObj, err := buildJinja2Template(core, fsys, []string{"cm.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "cm.yaml", jinja2TestStruct{Value: "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error executing the template: %v", err)
+	}
+	if buffer.String() != "value: hello\n" {
+		t.Fatalf("unexpected output: %q", buffer.String())
+	}
+}
+
+func TestJinja2TemplateRejectsDataThatDoesntEncodeAsObject(t *testing.T) {
+	_, err := jinja2Context([]int{1, 2, 3})
+	if err == nil {
+		t.Fatal("expected an error for data that doesn't encode as a JSON object")
+	}
+}
+
+func TestJinja2TemplateExecuteRejectsUnknownName(t *testing.T) {
+	fsys := fstest.MapFS{}
+	core := baseFuncs{fsys: fsys}
+	tmplObj, err := buildJinja2Template(core, fsys, nil)
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "missing.yaml", nil)
+	if err == nil {
+		t.Fatal("expected an error executing a template that doesn't exist")
+	}
+}
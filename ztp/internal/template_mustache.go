@@ -0,0 +1,78 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/cbroglie/mustache"
+	"golang.org/x/exp/slices"
+)
+
+// mustacheTemplate is the Template implementation for the EngineMustache engine. Mustache is
+// logic-less by design, so the custom functions registered via TemplateBuilder.AddFunc and
+// TemplateBuilder.AddFuncs aren't available to it. Don't create objects of this type directly, use
+// the NewTemplate builder instead.
+type mustacheTemplate struct {
+	baseFuncs
+	names    []string
+	compiled map[string]*mustache.Template
+}
+
+// buildMustacheTemplate parses the given files as Mustache templates and returns a Template that
+// executes them.
+func buildMustacheTemplate(core baseFuncs, fsys fs.FS, names []string) (result *mustacheTemplate, err error) {
+	t := &mustacheTemplate{
+		baseFuncs: core,
+		compiled:  map[string]*mustache.Template{},
+	}
+	for _, name := range names {
+		var data []byte
+		data, err = fs.ReadFile(fsys, name)
+		if err != nil {
+			return
+		}
+		var parsed *mustache.Template
+		parsed, err = mustache.ParseString(string(data))
+		if err != nil {
+			return
+		}
+		t.compiled[name] = parsed
+		t.names = append(t.names, name)
+	}
+	result = t
+	return
+}
+
+// Execute is part of the Template interface.
+func (t *mustacheTemplate) Execute(writer io.Writer, name string, data any) error {
+	parsed, ok := t.compiled[name]
+	if !ok {
+		return errTemplateNotFound(name)
+	}
+	return parsed.FRender(writer, data)
+}
+
+// Names is part of the Template interface.
+func (t *mustacheTemplate) Names() []string {
+	return slices.Clone(t.names)
+}
+
+// Render is part of the Template interface.
+func (t *mustacheTemplate) Render(outFS WritableFS,
+	mapping func(name string) (outPath string, data any, mode fs.FileMode, skip bool)) (RenderReport, error) {
+	return renderTemplate(t, outFS, mapping)
+}
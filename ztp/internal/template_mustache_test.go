@@ -0,0 +1,62 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMustacheTemplateExecutesWithMapData(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cm.yaml": &fstest.MapFile{
+			Data: []byte("value: {{ Value }}\n"),
+		},
+	}
+	core := baseFuncs{fsys: fsys}
+	tmplObj, err := buildMustacheTemplate(core, fsys, []string{"cm.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "cm.yaml", map[string]any{"Value": "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error executing the template: %v", err)
+	}
+	if buffer.String() != "value: hello\n" {
+		t.Fatalf("unexpected output: %q", buffer.String())
+	}
+
+	if len(tmplObj.Names()) != 1 || tmplObj.Names()[0] != "cm.yaml" {
+		t.Fatalf("unexpected names: %v", tmplObj.Names())
+	}
+}
+
+func TestMustacheTemplateExecuteRejectsUnknownName(t *testing.T) {
+	fsys := fstest.MapFS{}
+	core := baseFuncs{fsys: fsys}
+	tmplObj, err := buildMustacheTemplate(core, fsys, nil)
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = tmplObj.Execute(&buffer, "missing.yaml", nil)
+	if err == nil {
+		t.Fatal("expected an error executing a template that doesn't exist")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WritableFS is a filesystem that can be both read, to check the content that is already there, and
+// written to, to create or update files. It is used as the target of Template.Render.
+type WritableFS interface {
+	fs.FS
+
+	// WriteFile creates, or truncates and overwrites, the file with the given name, writing the
+	// given data to it and creating any missing parent directories.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// DirFS returns a WritableFS rooted at the given directory of the local filesystem.
+func DirFS(root string) WritableFS {
+	return &dirFS{
+		root: root,
+		read: os.DirFS(root),
+	}
+}
+
+type dirFS struct {
+	root string
+	read fs.FS
+}
+
+// Open is part of the fs.FS interface.
+func (d *dirFS) Open(name string) (fs.File, error) {
+	return d.read.Open(name)
+}
+
+// WriteFile is part of the WritableFS interface.
+func (d *dirFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return errors.New("path '" + name + "' isn't valid")
+	}
+	full := filepath.Join(d.root, filepath.FromSlash(name))
+	err := os.MkdirAll(filepath.Dir(full), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(full, data, perm)
+}
+
+// RenderReport summarizes the result of a call to Template.Render, listing the output paths that
+// were created, the ones whose content changed, and the ones that were already up to date and
+// therefore left untouched. The number of files in each category is simply the length of the
+// corresponding slice.
+type RenderReport struct {
+	Created   []string
+	Changed   []string
+	Unchanged []string
+}
+
+// renderTemplate walks the names of the given template, executes each one with the data returned by
+// the mapping function, and writes the result to outFS only when it differs from what is already
+// there, so that re-running it when nothing changed is a no-op. It is shared by all the Template
+// implementations.
+func renderTemplate(t Template, outFS WritableFS,
+	mapping func(name string) (outPath string, data any, mode fs.FileMode, skip bool)) (report RenderReport, err error) {
+	for _, name := range t.Names() {
+		outPath, data, mode, skip := mapping(name)
+		if skip {
+			continue
+		}
+		buffer := &bytes.Buffer{}
+		err = t.Execute(buffer, name, data)
+		if err != nil {
+			return
+		}
+		content := buffer.Bytes()
+		existing, readErr := fs.ReadFile(outFS, outPath)
+		switch {
+		case readErr == nil && bytes.Equal(existing, content):
+			report.Unchanged = append(report.Unchanged, outPath)
+			continue
+		case readErr != nil && !errors.Is(readErr, fs.ErrNotExist):
+			err = readErr
+			return
+		}
+		err = outFS.WriteFile(outPath, content, mode)
+		if err != nil {
+			return
+		}
+		if readErr == nil {
+			report.Changed = append(report.Changed, outPath)
+		} else {
+			report.Created = append(report.Created, outPath)
+		}
+	}
+	return
+}
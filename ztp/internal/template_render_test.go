@@ -0,0 +1,150 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// memWritableFS is a minimal in-memory WritableFS used to exercise renderTemplate without touching
+// the local filesystem.
+type memWritableFS struct {
+	files map[string][]byte
+}
+
+func newMemWritableFS() *memWritableFS {
+	return &memWritableFS{
+		files: map[string][]byte{},
+	}
+}
+
+func (m *memWritableFS) Open(name string) (fs.File, error) {
+	data, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memWritableFile{Reader: bytes.NewReader(data), name: name, size: int64(len(data))}, nil
+}
+
+func (m *memWritableFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+type memWritableFile struct {
+	*bytes.Reader
+	name string
+	size int64
+}
+
+func (f *memWritableFile) Stat() (fs.FileInfo, error) {
+	return memWritableFileInfo{name: f.name, size: f.size}, nil
+}
+
+func (f *memWritableFile) Close() error {
+	return nil
+}
+
+type memWritableFileInfo struct {
+	name string
+	size int64
+}
+
+func (i memWritableFileInfo) Name() string       { return i.name }
+func (i memWritableFileInfo) Size() int64        { return i.size }
+func (i memWritableFileInfo) Mode() fs.FileMode  { return 0o644 }
+func (i memWritableFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memWritableFileInfo) IsDir() bool        { return false }
+func (i memWritableFileInfo) Sys() any           { return nil }
+
+func newTestTextTemplate(t *testing.T) Template {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"cm.yaml.tmpl": &fstest.MapFile{
+			Data: []byte("value: {{ .Value }}\n"),
+		},
+	}
+	core := baseFuncs{
+		logger: logr.Discard(),
+		fsys:   fsys,
+	}
+	tmplObj, err := buildTextTemplate(core, fsys, []string{"cm.yaml.tmpl"}, nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error building the template: %v", err)
+	}
+	return tmplObj
+}
+
+func TestRenderCreatesChangesAndLeavesUnchanged(t *testing.T) {
+	tmplObj := newTestTextTemplate(t)
+	out := newMemWritableFS()
+
+	mappingWith := func(value string) func(string) (string, any, fs.FileMode, bool) {
+		return func(name string) (string, any, fs.FileMode, bool) {
+			return "cm.yaml", map[string]any{"Value": value}, 0o644, false
+		}
+	}
+
+	report, err := tmplObj.Render(out, mappingWith("hello"))
+	if err != nil {
+		t.Fatalf("unexpected error on first render: %v", err)
+	}
+	if len(report.Created) != 1 || len(report.Changed) != 0 || len(report.Unchanged) != 0 {
+		t.Fatalf("unexpected first report: %+v", report)
+	}
+
+	report, err = tmplObj.Render(out, mappingWith("hello"))
+	if err != nil {
+		t.Fatalf("unexpected error on second render: %v", err)
+	}
+	if len(report.Created) != 0 || len(report.Changed) != 0 || len(report.Unchanged) != 1 {
+		t.Fatalf("unexpected second report: %+v", report)
+	}
+
+	report, err = tmplObj.Render(out, mappingWith("world"))
+	if err != nil {
+		t.Fatalf("unexpected error on third render: %v", err)
+	}
+	if len(report.Created) != 0 || len(report.Changed) != 1 || len(report.Unchanged) != 0 {
+		t.Fatalf("unexpected third report: %+v", report)
+	}
+	if string(out.files["cm.yaml"]) != "value: world\n" {
+		t.Fatalf("unexpected file content: %q", out.files["cm.yaml"])
+	}
+}
+
+func TestRenderSkipsTemplatesMarkedToBeSkipped(t *testing.T) {
+	tmplObj := newTestTextTemplate(t)
+	out := newMemWritableFS()
+
+	report, err := tmplObj.Render(out, func(name string) (string, any, fs.FileMode, bool) {
+		return "cm.yaml", nil, 0o644, true
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Created) != 0 || len(report.Changed) != 0 || len(report.Unchanged) != 0 {
+		t.Fatalf("expected an empty report, got: %+v", report)
+	}
+	if _, ok := out.files["cm.yaml"]; ok {
+		t.Fatal("expected the skipped file not to be written")
+	}
+}
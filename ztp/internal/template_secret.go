@@ -0,0 +1,181 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SecretResolver knows how to fetch the raw bytes of a secret given its path. Implementations are
+// pluggable so that the `secret` template function can be backed by different secret stores, for
+// example environment variables, files on disk, HashiCorp Vault or Kubernetes secrets. Don't assume
+// that the returned bytes are printable text; use the `base64` function to encode them safely when
+// embedding them inside a manifest.
+type SecretResolver interface {
+	Resolve(path string) ([]byte, error)
+}
+
+// secretFunc is a template function that resolves the value of the given path using the secret
+// resolver configured via TemplateBuilder.SetSecretResolver, for example:
+//
+//	{{ secret "clusters/my-cluster/pull-secret" | base64 }}
+func (f *baseFuncs) secretFunc(path string) (result string, err error) {
+	if f.secret == nil {
+		err = errors.New("no secret resolver has been configured")
+		return
+	}
+	data, err := f.secret.Resolve(path)
+	if err != nil {
+		return
+	}
+	result = string(data)
+	return
+}
+
+// EnvSecretResolver is a SecretResolver that resolves a path to the value of the environment
+// variable with that name.
+type EnvSecretResolver struct {
+}
+
+// NewEnvSecretResolver creates a secret resolver that reads values from environment variables.
+func NewEnvSecretResolver() *EnvSecretResolver {
+	return &EnvSecretResolver{}
+}
+
+// Resolve is part of the SecretResolver interface.
+func (r *EnvSecretResolver) Resolve(path string) ([]byte, error) {
+	value, ok := os.LookupEnv(path)
+	if !ok {
+		return nil, fmt.Errorf("environment variable '%s' doesn't exist", path)
+	}
+	return []byte(value), nil
+}
+
+// FileSecretResolver is a SecretResolver that resolves a path to the contents of the file with that
+// path inside a given filesystem.
+type FileSecretResolver struct {
+	fsys fs.FS
+}
+
+// NewFileSecretResolver creates a secret resolver that reads values from files inside the given
+// filesystem.
+func NewFileSecretResolver(fsys fs.FS) *FileSecretResolver {
+	return &FileSecretResolver{
+		fsys: fsys,
+	}
+}
+
+// Resolve is part of the SecretResolver interface.
+func (r *FileSecretResolver) Resolve(path string) ([]byte, error) {
+	return fs.ReadFile(r.fsys, path)
+}
+
+// VaultSecretResolver is a SecretResolver that resolves a path to a field of a secret stored in the
+// key/value version 2 secrets engine of a HashiCorp Vault server. The path must have the form
+// `mount/key/field`, for example `secret/clusters/my-cluster/pull-secret`.
+type VaultSecretResolver struct {
+	client *vault.Client
+}
+
+// NewVaultSecretResolver creates a secret resolver that reads values from a HashiCorp Vault server
+// using the given client.
+func NewVaultSecretResolver(client *vault.Client) *VaultSecretResolver {
+	return &VaultSecretResolver{
+		client: client,
+	}
+}
+
+// splitVaultPath splits a path of the form `mount/key/field` into its three parts. The mount is
+// everything up to the first slash, and the field is everything after the last slash of what
+// remains, so that a key containing slashes, as is common with nested Vault KV paths, is kept
+// intact instead of being cut at the second slash of the whole path.
+func splitVaultPath(path string) (mount, key, field string, err error) {
+	mount, rest, ok := strings.Cut(path, "/")
+	if !ok {
+		err = fmt.Errorf("path '%s' doesn't have the form 'mount/key/field'", path)
+		return
+	}
+	separator := strings.LastIndex(rest, "/")
+	if separator < 0 {
+		err = fmt.Errorf("path '%s' doesn't have the form 'mount/key/field'", path)
+		return
+	}
+	key, field = rest[:separator], rest[separator+1:]
+	return
+}
+
+// Resolve is part of the SecretResolver interface.
+func (r *VaultSecretResolver) Resolve(path string) ([]byte, error) {
+	mount, key, field, err := splitVaultPath(path)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := r.client.KVv2(mount).Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[field]
+	if !ok {
+		return nil, fmt.Errorf("field '%s' doesn't exist in secret '%s' of mount '%s'", field, key, mount)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("field '%s' of secret '%s' of mount '%s' isn't a string", field, key, mount)
+	}
+	return []byte(text), nil
+}
+
+// KubernetesSecretResolver is a SecretResolver that resolves a path to a key of a Kubernetes secret
+// in a fixed namespace. The path must have the form `secret/key`, for example
+// `my-cluster-bmc-secret/password`.
+type KubernetesSecretResolver struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// NewKubernetesSecretResolver creates a secret resolver that reads values from Kubernetes secrets in
+// the given namespace using the given client.
+func NewKubernetesSecretResolver(client kubernetes.Interface, namespace string) *KubernetesSecretResolver {
+	return &KubernetesSecretResolver{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+// Resolve is part of the SecretResolver interface.
+func (r *KubernetesSecretResolver) Resolve(path string) ([]byte, error) {
+	name, key, ok := strings.Cut(path, "/")
+	if !ok {
+		return nil, fmt.Errorf("path '%s' doesn't have the form 'secret/key'", path)
+	}
+	secret, err := r.client.CoreV1().Secrets(r.namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("key '%s' doesn't exist in secret '%s' of namespace '%s'", key, name, r.namespace)
+	}
+	return value, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestSplitVaultPathKeepsNestedKeyIntact(t *testing.T) {
+	mount, key, field, err := splitVaultPath("secret/clusters/my-cluster/pull-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mount != "secret" {
+		t.Errorf("unexpected mount: got '%s', want 'secret'", mount)
+	}
+	if key != "clusters/my-cluster" {
+		t.Errorf("unexpected key: got '%s', want 'clusters/my-cluster'", key)
+	}
+	if field != "pull-secret" {
+		t.Errorf("unexpected field: got '%s', want 'pull-secret'", field)
+	}
+}
+
+func TestSplitVaultPathRejectsPathsWithoutEnoughSegments(t *testing.T) {
+	cases := []string{"secret", "secret/key"}
+	for _, path := range cases {
+		_, _, _, err := splitVaultPath(path)
+		if err == nil {
+			t.Errorf("expected an error splitting path '%s'", path)
+		}
+	}
+}
+
+func TestEnvSecretResolverResolvesExistingVariable(t *testing.T) {
+	t.Setenv("ZTP_TEST_SECRET", "value")
+	resolver := NewEnvSecretResolver()
+	data, err := resolver.Resolve("ZTP_TEST_SECRET")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "value" {
+		t.Fatalf("unexpected value: %s", data)
+	}
+}
+
+func TestFileSecretResolverResolvesExistingFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pull-secret": &fstest.MapFile{
+			Data: []byte(`{"auths":{}}`),
+		},
+	}
+	resolver := NewFileSecretResolver(fsys)
+	data, err := resolver.Resolve("pull-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"auths":{}}` {
+		t.Fatalf("unexpected value: %s", data)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in
+compliance with the License. You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under the License is
+distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing permissions and limitations under the
+License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	tmpl "text/template"
+
+	"golang.org/x/exp/slices"
+)
+
+// textTemplate is the Template implementation for the EngineGoText engine. It is based on the
+// standard library `text/template` package. Don't create objects of this type directly, use the
+// NewTemplate builder instead.
+type textTemplate struct {
+	baseFuncs
+	names    []string
+	template *tmpl.Template
+	strict   bool
+}
+
+// buildTextTemplate parses the given files using the `text/template` package and returns a
+// Template that executes them.
+func buildTextTemplate(core baseFuncs, fsys fs.FS, names []string, funcs tmpl.FuncMap,
+	strict bool) (result *textTemplate, err error) {
+	t := &textTemplate{
+		baseFuncs: core,
+		template:  tmpl.New(""),
+		strict:    strict,
+	}
+	if strict {
+		t.template.Option("missingkey=error")
+	}
+	t.template.Funcs(t.funcMap())
+	t.template.Funcs(tmpl.FuncMap{
+		"execute": t.executeFunc,
+		"include": t.includeFunc,
+	})
+	if funcs != nil {
+		t.template.Funcs(funcs)
+	}
+	for _, name := range names {
+		err = t.parseFile(fsys, name)
+		if err != nil {
+			return
+		}
+	}
+	result = t
+	return
+}
+
+func (t *textTemplate) parseFile(fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	text := string(data)
+	_, err = t.template.New(name).Parse(text)
+	if err != nil {
+		return err
+	}
+	t.names = append(t.names, name)
+	detail := t.logger.V(2)
+	if detail.Enabled() {
+		detail.Info(
+			"Parsed template",
+			"name", name,
+			"text", text,
+		)
+	}
+	return nil
+}
+
+// Execute is part of the Template interface.
+func (t *textTemplate) Execute(writer io.Writer, name string, data any) (err error) {
+	buffer := &bytes.Buffer{}
+	err = t.template.ExecuteTemplate(buffer, name, data)
+	if err != nil {
+		if t.strict {
+			err = wrapExecutionError(name, err)
+		}
+		return
+	}
+	_, err = buffer.WriteTo(writer)
+	if err != nil {
+		return
+	}
+	detail := t.logger.V(2)
+	if detail.Enabled() {
+		detail.Info(
+			"Executed template",
+			"name", name,
+			"data", data,
+			"text", buffer.String(),
+		)
+	}
+	return
+}
+
+// Names is part of the Template interface.
+func (t *textTemplate) Names() []string {
+	return slices.Clone(t.names)
+}
+
+// Render is part of the Template interface.
+func (t *textTemplate) Render(outFS WritableFS,
+	mapping func(name string) (outPath string, data any, mode fs.FileMode, skip bool)) (RenderReport, error) {
+	return renderTemplate(t, outFS, mapping)
+}
+
+// executeFunc is a template function similar to template.ExecuteTemplate but it returns the result
+// instead of writing it to the output. That is useful when some processing is needed after that,
+// for example, to encode the result using Base64:
+//
+//	{{ execute "my.tmpl" . | base64 }}
+func (t *textTemplate) executeFunc(name string, data any) (result string, err error) {
+	buffer := &bytes.Buffer{}
+	executed := t.template.Lookup(name)
+	err = executed.Execute(buffer, data)
+	if err != nil {
+		return
+	}
+	result = buffer.String()
+	return
+}
+
+// includeFunc is a template function that reads the file at the given path, executes it as an
+// ad-hoc template passing it the given data, and returns the result. This is useful to keep large
+// embedded blobs, such as ignition configs or kustomize overlays, in separate files under the
+// template filesystem instead of inline, and is typically combined with the `indent` function:
+//
+//	{{ include "files/machine-config.yaml.tmpl" . | indent 4 }}
+func (t *textTemplate) includeFunc(path string, data any) (result string, err error) {
+	text, err := t.readSandboxedFile(path)
+	if err != nil {
+		return
+	}
+	clone, err := t.template.Clone()
+	if err != nil {
+		return
+	}
+	included, err := clone.New(path).Parse(string(text))
+	if err != nil {
+		return
+	}
+	buffer := &bytes.Buffer{}
+	err = included.Execute(buffer, data)
+	if err != nil {
+		return
+	}
+	result = buffer.String()
+	return
+}